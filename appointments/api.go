@@ -0,0 +1,71 @@
+package appointments
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// registerAPI mounts the read-only history/stats REST endpoints backed by
+// store onto mux, alongside the WebSocket handler.
+func registerAPI(mux *http.ServeMux, store Store) {
+	mux.HandleFunc("/api/services", func(w http.ResponseWriter, r *http.Request) {
+		services, err := store.Services(r.Context())
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeJSON(w, services)
+	})
+
+	mux.HandleFunc("/api/history", func(w http.ResponseWriter, r *http.Request) {
+		serviceID := r.URL.Query().Get("service")
+		if serviceID == "" {
+			http.Error(w, "missing required \"service\" query parameter", http.StatusBadRequest)
+			return
+		}
+
+		since := time.Time{}
+		if rawSince := r.URL.Query().Get("since"); rawSince != "" {
+			parsed, err := time.Parse(time.RFC3339, rawSince)
+			if err != nil {
+				http.Error(w, "invalid \"since\" query parameter, expected RFC3339", http.StatusBadRequest)
+				return
+			}
+			since = parsed
+		}
+
+		history, err := store.History(r.Context(), serviceID, since)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeJSON(w, history)
+	})
+
+	mux.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+		serviceID := r.URL.Query().Get("service")
+		if serviceID == "" {
+			http.Error(w, "missing required \"service\" query parameter", http.StatusBadRequest)
+			return
+		}
+
+		stats, err := store.Stats(r.Context(), serviceID)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		writeJSON(w, stats)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -1,11 +1,11 @@
 package appointments
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"os/exec"
 	"path"
 	"strconv"
 	"strings"
@@ -17,25 +17,64 @@ import (
 )
 
 var (
-	connectedClients = make(map[*websocket.Conn]struct{})
+	// connectedClients maps each open WebSocket connection to its
+	// subscription. A client with no subscription yet receives nothing.
+	connectedClients = make(map[*websocket.Conn]*subscription)
 	clientsMutex     sync.Mutex
-	lastMessage      = Message{
-		Time:                    datetimeToJSON(time.Now()),
-		Status:                  200,
-		AppointmentDates:        []string{},
-		LastAppointmentsFoundOn: nil,
-	}
-	refreshDelay = 180 // Minimum allowed by Berlin.de's IKT-ZMS team.
-	timezone     = mustLoadLocation("Europe/Berlin")
+
+	lastMessages  = make(map[string]Message)
+	lastMessageMu sync.Mutex
+
+	timezone = mustLoadLocation("Europe/Berlin")
+)
+
+// httpClient is used for all outbound requests to Berlin.de. The timeout
+// bounds a stalled upstream response so a cancelled ctx isn't the only
+// thing standing between a hung request and shutdown.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// WebSocket keepalive tuning: pingInterval must stay well under pongWait so
+// a missed pong has time to be detected before the peer is considered dead.
+const (
+	pongWait     = 60 * time.Second
+	pingInterval = 30 * time.Second
+	writeWait    = 10 * time.Second
+	shutdownWait = 10 * time.Second
 )
 
 // Message represents the structure of the message sent to clients
 type Message struct {
 	Time                    string   `json:"time"`
 	Status                  int      `json:"status"`
+	ServiceID               string   `json:"serviceId"`
 	AppointmentDates        []string `json:"appointmentDates"`
+	NewAppointmentDates     []string `json:"newAppointmentDates"`
 	Message                 string   `json:"message"`
 	LastAppointmentsFoundOn *string  `json:"lastAppointmentsFoundOn"`
+	NextPollAt              string   `json:"nextPollAt"`
+}
+
+// subscribeMessage is what a client sends over the WebSocket to select
+// which services it wants updates for, e.g.
+// {"type": "subscribe", "services": ["120686", "327537"], "newOnly": true}
+// NewOnly is a pointer so an absent field falls back to the server's
+// --new-only default instead of always resetting it to false.
+type subscribeMessage struct {
+	Type     string   `json:"type"`
+	Services []string `json:"services"`
+	NewOnly  *bool    `json:"newOnly"`
+}
+
+// subscription is the per-connection state tracked for each client.
+type subscription struct {
+	services map[string]struct{}
+	newOnly  bool
+}
+
+// Service identifies a single Berlin.de service page being watched.
+type Service struct {
+	ID  string
+	URL string
 }
 
 func mustLoadLocation(name string) *time.Location {
@@ -61,11 +100,28 @@ func getHeaders(email string, scriptID string) map[string][]string {
 	}
 }
 
-func getAppointmentsURL(servicePageURL string) string {
+// getServiceID extracts the numeric service ID from a service.berlin.de page URL.
+func getServiceID(servicePageURL string) string {
 	trimmed := strings.TrimSuffix(servicePageURL, "/")
 	splitted := strings.Split(trimmed, "/")
-	serviceID := splitted[len(splitted)-1]
-	return fmt.Sprintf("https://service.berlin.de/terminvereinbarung/termin/all/%s/", serviceID)
+	return splitted[len(splitted)-1]
+}
+
+func getAppointmentsURL(servicePageURL string) string {
+	return fmt.Sprintf("https://service.berlin.de/terminvereinbarung/termin/all/%s/", getServiceID(servicePageURL))
+}
+
+// NewServices builds a Service entry for every service page URL, deriving
+// its ID from the URL.
+func NewServices(servicePageURLs []string) []Service {
+	services := make([]Service, 0, len(servicePageURLs))
+	for _, url := range servicePageURLs {
+		services = append(services, Service{
+			ID:  getServiceID(url),
+			URL: url,
+		})
+	}
+	return services
 }
 
 func parseAppointmentDates(pageContent string) []string {
@@ -91,19 +147,18 @@ func parseAppointmentDates(pageContent string) []string {
 	return appointmentDates
 }
 
-func getAppointments(appointmentsURL string, email string, scriptID string) ([]string, error) {
+func getAppointments(ctx context.Context, appointmentsURL string, email string, scriptID string) ([]string, error) {
 	today := time.Now().In(timezone)
 	nextMonth := time.Date(today.Year(), (today.Month()%12)+1, 1, 0, 0, 0, 0, timezone)
 	nextMonthTimestamp := nextMonth.Unix()
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", appointmentsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", appointmentsURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
 	req.Header = getHeaders(email, scriptID)
-	responsePage1, err := client.Do(req)
+	responsePage1, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching appointments page 1: %v", err)
 	}
@@ -118,13 +173,13 @@ func getAppointments(appointmentsURL string, email string, scriptID string) ([]s
 	page1Dates := parseAppointmentDates(string(body))
 
 	page2URL := fmt.Sprintf("https://service.berlin.de/terminvereinbarung/termin/day/%d/", nextMonthTimestamp)
-	req, err = http.NewRequest("GET", page2URL, nil)
+	req, err = http.NewRequestWithContext(ctx, "GET", page2URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request for page 2: %v", err)
 	}
 
 	req.Header = getHeaders(email, scriptID)
-	responsePage2, err := client.Do(req)
+	responsePage2, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching appointments page 2: %v", err)
 	}
@@ -141,27 +196,28 @@ func getAppointments(appointmentsURL string, email string, scriptID string) ([]s
 	return appointments, nil
 }
 
-func lookForAppointments(appointmentsURL string, email string, scriptID string, quiet bool) Message {
+func lookForAppointments(ctx context.Context, service Service, filter *seenFilter, email string, scriptID string) Message {
 	var result Message
 
-	appointments, err := getAppointments(appointmentsURL, email, scriptID)
+	appointments, err := getAppointments(ctx, getAppointmentsURL(service.URL), email, scriptID)
 	if err != nil {
 		result = Message{
 			Time:             datetimeToJSON(time.Now()),
 			Status:           502,
+			ServiceID:        service.ID,
 			Message:          fmt.Sprintf("Could not fetch results from Berlin.de - %v", err),
 			AppointmentDates: []string{},
 		}
 	} else {
-		fmt.Printf("Found %d appointments: %v\n", len(appointments), appointments)
-		if len(appointments) > 0 && !quiet {
-			beep()
-		}
+		newAppointments := filter.filterNew(appointments)
+		fmt.Printf("[%s] Found %d appointments (%d new): %v\n", service.ID, len(appointments), len(newAppointments), appointments)
 		result = Message{
-			Time:             datetimeToJSON(time.Now()),
-			Status:           200,
-			Message:          "",
-			AppointmentDates: appointments,
+			Time:                datetimeToJSON(time.Now()),
+			Status:              200,
+			ServiceID:           service.ID,
+			Message:             "",
+			AppointmentDates:    appointments,
+			NewAppointmentDates: newAppointments,
 		}
 	}
 
@@ -175,78 +231,251 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func WatchForAppointments(servicePageURL string, email string, scriptID string, serverPort int, quiet bool) {
-	fmt.Printf("Getting appointment URL for %s\n", servicePageURL)
-	appointmentsURL := getAppointmentsURL(servicePageURL)
-	fmt.Printf("URL found: %s\n", appointmentsURL)
+// broadcast sends msg to every connected client subscribed to msg.ServiceID,
+// skipping clients in new-only mode when msg has no new appointments.
+func broadcast(msg Message) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	for client, sub := range connectedClients {
+		if _, subscribed := sub.services[msg.ServiceID]; !subscribed {
+			continue
+		}
+		if sub.newOnly && len(msg.NewAppointmentDates) == 0 {
+			continue
+		}
+		if err := client.WriteJSON(msg); err != nil {
+			fmt.Printf("Error writing JSON to WebSocket: %v\n", err)
+		}
+	}
+}
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		ws, err := upgrader.Upgrade(w, r, nil)
-		if err != nil {
-			fmt.Printf("Error upgrading connection to WebSocket: %v\n", err)
-			return
+func handleWebSocket(ctx context.Context, w http.ResponseWriter, r *http.Request, newOnlyDefault bool) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fmt.Printf("Error upgrading connection to WebSocket: %v\n", err)
+		return
+	}
+	defer ws.Close()
+
+	ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		ws.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	clientsMutex.Lock()
+	connectedClients[ws] = &subscription{services: make(map[string]struct{}), newOnly: newOnlyDefault}
+	clientsMutex.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go pingConnection(ctx, ws, done)
+
+	for {
+		var sub subscribeMessage
+		if err := ws.ReadJSON(&sub); err != nil {
+			break
 		}
-		defer ws.Close()
 
-		clientsMutex.Lock()
-		connectedClients[ws] = struct{}{}
-		clientsMutex.Unlock()
+		if sub.Type != "subscribe" {
+			continue
+		}
 
-		// Send the latest results to the newly connected client
-		err = ws.WriteJSON(lastMessage)
-		if err != nil {
-			fmt.Printf("Error writing JSON to WebSocket: %v\n", err)
-			return
+		services := make(map[string]struct{}, len(sub.Services))
+		for _, serviceID := range sub.Services {
+			services[serviceID] = struct{}{}
 		}
 
-		// Wait for the client to close the connection
-		_, _, err = ws.ReadMessage()
-		if err != nil {
-			fmt.Printf("Error reading message from WebSocket: %v\n", err)
+		newOnly := newOnlyDefault
+		if sub.NewOnly != nil {
+			newOnly = *sub.NewOnly
 		}
 
 		clientsMutex.Lock()
-		delete(connectedClients, ws)
+		connectedClients[ws] = &subscription{services: services, newOnly: newOnly}
 		clientsMutex.Unlock()
-	})
 
-	go func() {
-		err := http.ListenAndServe(fmt.Sprintf(":%d", serverPort), nil)
-		if err != nil {
-			log.Fatalf("Error starting server: %v", err)
+		// Send whatever we already know about the newly subscribed services.
+		lastMessageMu.Lock()
+		for serviceID := range services {
+			if msg, ok := lastMessages[serviceID]; ok {
+				if err := ws.WriteJSON(msg); err != nil {
+					fmt.Printf("Error writing JSON to WebSocket: %v\n", err)
+				}
+			}
 		}
-	}()
+		lastMessageMu.Unlock()
+	}
 
-	fmt.Printf("Server is running on port %d. Looking for appointments every %d seconds.\n", serverPort, refreshDelay)
+	clientsMutex.Lock()
+	delete(connectedClients, ws)
+	clientsMutex.Unlock()
+}
+
+// pingConnection sends a WebSocket ping every pingInterval so a client that
+// stops responding gets its read deadline tripped instead of blocking its
+// handleWebSocket goroutine forever. It stops when done is closed (the
+// connection is gone) or ctx is cancelled (the server is shutting down).
+func pingConnection(ctx context.Context, ws *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
 
 	for {
-		lastApptsFoundOn := lastMessage.LastAppointmentsFoundOn
-		lastMessage = lookForAppointments(appointmentsURL, email, scriptID, quiet)
+		select {
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// broadcastShutdown tells every connected client the server is going away
+// so browser clients know to reconnect rather than silently hang.
+func broadcastShutdown() {
+	msg := Message{
+		Time:    datetimeToJSON(time.Now()),
+		Status:  503,
+		Message: "server shutting down",
+	}
 
-		if len(lastMessage.AppointmentDates) > 0 {
-			now := time.Now().In(timezone)
-			*lastMessage.LastAppointmentsFoundOn = now.Format(time.RFC3339)
-		} else {
-			lastMessage.LastAppointmentsFoundOn = lastApptsFoundOn
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+	for client := range connectedClients {
+		client.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := client.WriteJSON(msg); err != nil {
+			fmt.Printf("Error writing shutdown message to WebSocket: %v\n", err)
 		}
+		client.Close()
+	}
+}
 
-		clientsMutex.Lock()
-		for client := range connectedClients {
-			err := client.WriteJSON(lastMessage)
-			if err != nil {
-				fmt.Printf("Error writing JSON to WebSocket: %v\n", err)
-			}
+// watchService polls a single service on an adaptive schedule (see
+// scheduler), never faster than minPollInterval, and broadcasts every
+// result to subscribed clients.
+func watchService(ctx context.Context, service Service, email string, scriptID string, quiet bool, notifiers []Notifier, store Store) {
+	filter := newSeenFilter()
+	sched := newScheduler(service.ID, store)
+
+	for {
+		if ctx.Err() != nil {
+			return
 		}
-		clientsMutex.Unlock()
 
-		time.Sleep(time.Second * time.Duration(refreshDelay))
+		pollStarted := time.Now()
+		msg := lookForAppointments(ctx, service, filter, email, scriptID)
+		latency := time.Since(pollStarted)
+
+		if err := store.RecordObservation(ctx, Observation{
+			Time:           pollStarted,
+			ServiceID:      service.ID,
+			HTTPStatus:     msg.Status,
+			SlotTimestamps: msg.AppointmentDates,
+			LatencyMs:      latency.Milliseconds(),
+		}); err != nil {
+			fmt.Printf("Error recording observation: %v\n", err)
+		}
+
+		lastMessageMu.Lock()
+		previous, hadPrevious := lastMessages[service.ID]
+		if len(msg.AppointmentDates) > 0 {
+			found := time.Now().In(timezone).Format(time.RFC3339)
+			msg.LastAppointmentsFoundOn = &found
+		} else if hadPrevious {
+			msg.LastAppointmentsFoundOn = previous.LastAppointmentsFoundOn
+		}
+		lastMessageMu.Unlock()
+
+		// sched.next may hit the store (a real DB round-trip for
+		// SQLiteStore), so it must run outside lastMessageMu: that lock is
+		// shared by every service's goroutine and would otherwise serialize
+		// all of them on one service's DB query.
+		interval := sched.next(ctx, msg.Status)
+		msg.NextPollAt = time.Now().Add(interval).Format(time.RFC3339)
+
+		lastMessageMu.Lock()
+		lastMessages[service.ID] = msg
+		lastMessageMu.Unlock()
+
+		broadcast(msg)
+
+		if len(msg.NewAppointmentDates) > 0 {
+			notifyAll(notifiers, msg, quiet)
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
-func beep() {
-	cmd := exec.Command("beep") // Replace with your system's sound command or use a beep package
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("Error playing beep sound: %v\n", err)
+// WatchForAppointments starts polling every service in servicePageURLs
+// concurrently, one goroutine per service, and serves a WebSocket plus a
+// read-only history/stats REST API on serverPort. When newOnly is true,
+// clients default to only being pushed messages that contain appointments
+// the server hasn't seen before, unless a client overrides this
+// per-subscription. Every notifier in notifiers is fanned out to whenever a
+// service reports new appointments. Every poll result is recorded to store.
+//
+// WatchForAppointments returns once ctx is cancelled and every poller and
+// the HTTP server have shut down.
+func WatchForAppointments(ctx context.Context, servicePageURLs []string, email string, scriptID string, serverPort int, quiet bool, newOnly bool, notifiers []Notifier, store Store) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	services := NewServices(servicePageURLs)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSocket(ctx, w, r, newOnly)
+	})
+	registerAPI(mux, store)
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", serverPort),
+		Handler: mux,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	fmt.Printf("Server is running on port %d. Watching %d service(s), polling no more often than every %s.\n", serverPort, len(services), minPollInterval)
+
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func(service Service) {
+			defer wg.Done()
+			watchService(ctx, service, email, scriptID, quiet, notifiers, store)
+		}(service)
 	}
+
+	select {
+	case <-ctx.Done():
+		fmt.Println("Shutting down...")
+	case err := <-serverErr:
+		fmt.Printf("Error starting server: %v\n", err)
+		cancel()
+	}
+
+	broadcastShutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownWait)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Error shutting down server: %v\n", err)
+	}
+
+	wg.Wait()
 }
@@ -0,0 +1,60 @@
+package appointments
+
+import (
+	"sync"
+	"time"
+
+	"github.com/willf/bloom"
+)
+
+const (
+	bloomEstimatedItems    = 10000
+	bloomFalsePositiveRate = 0.01
+	bloomRotationInterval  = 6 * time.Hour
+)
+
+// seenFilter tracks which appointment timestamps have recently been
+// observed using two generations of Bloom filters. Every bloomRotationInterval
+// the older generation is dropped, so entries eventually age out instead of
+// accumulating forever and degrading the filter's false-positive rate.
+type seenFilter struct {
+	mu       sync.Mutex
+	current  *bloom.BloomFilter
+	previous *bloom.BloomFilter
+}
+
+func newSeenFilter() *seenFilter {
+	f := &seenFilter{
+		current:  bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate),
+		previous: bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate),
+	}
+	go f.rotateLoop()
+	return f
+}
+
+func (f *seenFilter) rotateLoop() {
+	ticker := time.NewTicker(bloomRotationInterval)
+	for range ticker.C {
+		f.mu.Lock()
+		f.previous = f.current
+		f.current = bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate)
+		f.mu.Unlock()
+	}
+}
+
+// filterNew returns the subset of appointments that haven't been seen by
+// either Bloom filter generation, recording all of them as seen either way.
+func (f *seenFilter) filterNew(appointments []string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var fresh []string
+	for _, appointment := range appointments {
+		key := []byte(appointment)
+		if !f.current.Test(key) && !f.previous.Test(key) {
+			fresh = append(fresh, appointment)
+		}
+		f.current.Add(key)
+	}
+	return fresh
+}
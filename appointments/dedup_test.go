@@ -0,0 +1,49 @@
+package appointments
+
+import (
+	"testing"
+
+	"github.com/willf/bloom"
+)
+
+func newTestSeenFilter() *seenFilter {
+	// Built directly rather than via newSeenFilter so the test doesn't spin
+	// up a rotation goroutine it has no need of.
+	return &seenFilter{
+		current:  bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate),
+		previous: bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate),
+	}
+}
+
+func TestFilterNewFirstSeenIsFresh(t *testing.T) {
+	f := newTestSeenFilter()
+	fresh := f.filterNew([]string{"a", "b"})
+	if len(fresh) != 2 {
+		t.Fatalf("filterNew on first sighting = %v, want both entries fresh", fresh)
+	}
+}
+
+func TestFilterNewAlreadySeenIsDropped(t *testing.T) {
+	f := newTestSeenFilter()
+	f.filterNew([]string{"a"})
+
+	fresh := f.filterNew([]string{"a", "b"})
+	if len(fresh) != 1 || fresh[0] != "b" {
+		t.Fatalf("filterNew after seeing %q = %v, want only %q", "a", fresh, "b")
+	}
+}
+
+func TestFilterNewChecksPreviousGeneration(t *testing.T) {
+	f := newTestSeenFilter()
+	f.current.Add([]byte("stale"))
+
+	// Simulate a rotation: "stale" moves from current to previous, and a
+	// fresh empty filter takes over as current.
+	f.previous = f.current
+	f.current = bloom.NewWithEstimates(bloomEstimatedItems, bloomFalsePositiveRate)
+
+	fresh := f.filterNew([]string{"stale", "new"})
+	if len(fresh) != 1 || fresh[0] != "new" {
+		t.Fatalf("filterNew after rotation = %v, want only %q", fresh, "new")
+	}
+}
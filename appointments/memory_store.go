@@ -0,0 +1,64 @@
+package appointments
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory Store. It's lost on restart; use SQLiteStore
+// when observations need to survive the process.
+type MemoryStore struct {
+	mu           sync.Mutex
+	observations map[string][]Observation
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{observations: make(map[string][]Observation)}
+}
+
+func (s *MemoryStore) RecordObservation(ctx context.Context, obs Observation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observations[obs.ServiceID] = append(s.observations[obs.ServiceID], obs)
+	return nil
+}
+
+func (s *MemoryStore) History(ctx context.Context, serviceID string, since time.Time) ([]Observation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var history []Observation
+	for _, obs := range s.observations[serviceID] {
+		if !obs.Time.Before(since) {
+			history = append(history, obs)
+		}
+	}
+	return history, nil
+}
+
+func (s *MemoryStore) Services(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	services := make([]string, 0, len(s.observations))
+	for serviceID := range s.observations {
+		services = append(services, serviceID)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+func (s *MemoryStore) Stats(ctx context.Context, serviceID string) (Stats, error) {
+	s.mu.Lock()
+	observations := append([]Observation(nil), s.observations[serviceID]...)
+	s.mu.Unlock()
+
+	return statsFromObservations(serviceID, observations), nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
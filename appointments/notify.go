@@ -0,0 +1,59 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Notifier delivers a Message to some external channel, such as a webhook,
+// chat app, or email inbox.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+const (
+	notifyTimeout     = 10 * time.Second
+	notifyMaxAttempts = 3
+	notifyBaseBackoff = 500 * time.Millisecond
+)
+
+// notifyAll fans msg out to every notifier concurrently. Each notifier gets
+// its own timeout and retries with exponential backoff, so a slow or
+// failing notifier can't stall the others or the poll loop. quiet only
+// suppresses the local BeepNotifier; a user's configured remote notifiers
+// (webhook, Telegram, ntfy, email) always fire, since quiet is about
+// console output, not about disabling the notifications they set up.
+func notifyAll(notifiers []Notifier, msg Message, quiet bool) {
+	for _, n := range notifiers {
+		if quiet {
+			if _, isBeep := n.(*BeepNotifier); isBeep {
+				continue
+			}
+		}
+		go notifyWithRetry(n, msg)
+	}
+}
+
+// formatNotificationText renders a Message as a short human-readable line
+// for notifiers that deliver plain text rather than JSON.
+func formatNotificationText(msg Message) string {
+	if len(msg.NewAppointmentDates) == 0 {
+		return fmt.Sprintf("No new appointments for service %s.", msg.ServiceID)
+	}
+	return fmt.Sprintf("%d new appointment(s) for service %s: %v", len(msg.NewAppointmentDates), msg.ServiceID, msg.NewAppointmentDates)
+}
+
+func notifyWithRetry(n Notifier, msg Message) {
+	var err error
+	for attempt := 0; attempt < notifyMaxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		err = n.Notify(ctx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		time.Sleep(notifyBaseBackoff * time.Duration(1<<attempt))
+	}
+	fmt.Printf("Error delivering notification after %d attempts: %v\n", notifyMaxAttempts, err)
+}
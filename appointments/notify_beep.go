@@ -0,0 +1,63 @@
+//go:build !noaudio
+
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/generators"
+	"github.com/faiface/beep/speaker"
+)
+
+const (
+	beepSampleRate = beep.SampleRate(44100)
+	beepFrequency  = 880.0
+	beepDuration   = 300 * time.Millisecond
+)
+
+var speakerInit sync.Once
+
+// BeepNotifier plays a short tone on the local machine's speaker. It
+// replaces the old exec.Command("beep") shell-out with a cross-platform Go
+// audio library so it also works on systems without a "beep" binary.
+//
+// This pulls in a CGo audio backend (ALSA on Linux) at build time and a
+// working audio device at run time, neither of which a headless server
+// deployment has. Build with -tags noaudio to get the no-op stand-in in
+// notify_beep_noaudio.go instead.
+type BeepNotifier struct{}
+
+func (n *BeepNotifier) Notify(ctx context.Context, msg Message) error {
+	if len(msg.NewAppointmentDates) == 0 {
+		return nil
+	}
+
+	var initErr error
+	speakerInit.Do(func() {
+		initErr = speaker.Init(beepSampleRate, beepSampleRate.N(beepDuration))
+	})
+	if initErr != nil {
+		return fmt.Errorf("error initializing speaker: %v", initErr)
+	}
+
+	tone, err := generators.SinTone(beepSampleRate, int(beepFrequency))
+	if err != nil {
+		return fmt.Errorf("error generating tone: %v", err)
+	}
+
+	done := make(chan struct{})
+	speaker.Play(beep.Seq(beep.Take(beepSampleRate.N(beepDuration), tone), beep.Callback(func() {
+		close(done)
+	})))
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
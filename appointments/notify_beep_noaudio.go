@@ -0,0 +1,22 @@
+//go:build noaudio
+
+package appointments
+
+import (
+	"context"
+	"fmt"
+)
+
+// BeepNotifier is a no-op stand-in used when built with -tags noaudio, for
+// deployments (e.g. a headless server) that don't want the CGo/ALSA
+// dependency pulled in by the real beep-playing implementation in
+// notify_beep.go.
+type BeepNotifier struct{}
+
+func (n *BeepNotifier) Notify(ctx context.Context, msg Message) error {
+	if len(msg.NewAppointmentDates) == 0 {
+		return nil
+	}
+	fmt.Printf("[%s] %d new appointment(s) (beep notifier built with -tags noaudio, not playing a sound)\n", msg.ServiceID, len(msg.NewAppointmentDates))
+	return nil
+}
@@ -0,0 +1,80 @@
+package appointments
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// NotifierConfig describes which notifier backends to enable and how to
+// reach them. Every section is optional; omit a section to leave that
+// backend disabled.
+type NotifierConfig struct {
+	Webhook *struct {
+		URL    string `json:"url"`
+		Secret string `json:"secret"`
+	} `json:"webhook"`
+
+	Telegram *struct {
+		BotToken string `json:"botToken"`
+		ChatID   string `json:"chatId"`
+	} `json:"telegram"`
+
+	Ntfy *struct {
+		Topic     string `json:"topic"`
+		ServerURL string `json:"serverUrl"`
+	} `json:"ntfy"`
+
+	Email *struct {
+		Host     string   `json:"host"`
+		Port     int      `json:"port"`
+		Username string   `json:"username"`
+		Password string   `json:"password"`
+		From     string   `json:"from"`
+		To       []string `json:"to"`
+	} `json:"email"`
+}
+
+// LoadNotifierConfig reads a NotifierConfig from a JSON file.
+func LoadNotifierConfig(path string) (NotifierConfig, error) {
+	var cfg NotifierConfig
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("error reading notifier config: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("error parsing notifier config: %v", err)
+	}
+	return cfg, nil
+}
+
+// BuildNotifiers turns a NotifierConfig into the enabled Notifier backends.
+// A BeepNotifier is always included, matching the tool's original
+// local-beep-only behavior.
+func BuildNotifiers(cfg NotifierConfig) []Notifier {
+	notifiers := []Notifier{&BeepNotifier{}}
+
+	if cfg.Webhook != nil {
+		notifiers = append(notifiers, &WebhookNotifier{URL: cfg.Webhook.URL, Secret: cfg.Webhook.Secret})
+	}
+	if cfg.Telegram != nil {
+		notifiers = append(notifiers, &TelegramNotifier{BotToken: cfg.Telegram.BotToken, ChatID: cfg.Telegram.ChatID})
+	}
+	if cfg.Ntfy != nil {
+		notifiers = append(notifiers, &NtfyNotifier{Topic: cfg.Ntfy.Topic, ServerURL: cfg.Ntfy.ServerURL})
+	}
+	if cfg.Email != nil {
+		notifiers = append(notifiers, &EmailNotifier{
+			Host:     cfg.Email.Host,
+			Port:     cfg.Email.Port,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+			To:       cfg.Email.To,
+		})
+	}
+
+	return notifiers
+}
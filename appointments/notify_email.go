@@ -0,0 +1,44 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+)
+
+// EmailNotifier delivers a Message over SMTP.
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, msg Message) error {
+	addr := fmt.Sprintf("%s:%d", n.Host, n.Port)
+	auth := smtp.PlainAuth("", n.Username, n.Password, n.Host)
+
+	subject := fmt.Sprintf("Subject: Appointment update for service %s\r\n", msg.ServiceID)
+	body := fmt.Sprintf("%s\r\n\r\n%s\r\n", subject, formatNotificationText(msg))
+
+	// net/smtp has no context support, so a stalled dial or SMTP conversation
+	// is raced against ctx here instead. If ctx wins, Notify returns but the
+	// SendMail goroutine is left to finish (or fail) on its own; it's not
+	// killed, since net/smtp gives us no way to abort it mid-flight.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, n.From, n.To, []byte(body))
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("error sending email: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
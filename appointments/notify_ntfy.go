@@ -0,0 +1,38 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// NtfyNotifier publishes a Message to a ntfy.sh (or self-hosted ntfy) topic.
+type NtfyNotifier struct {
+	Topic     string
+	ServerURL string // defaults to https://ntfy.sh
+}
+
+func (n *NtfyNotifier) Notify(ctx context.Context, msg Message) error {
+	server := n.ServerURL
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	endpoint := fmt.Sprintf("%s/%s", strings.TrimSuffix(server, "/"), n.Topic)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(formatNotificationText(msg)))
+	if err != nil {
+		return fmt.Errorf("error creating ntfy request: %v", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error publishing to ntfy: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("ntfy returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TelegramNotifier sends a Message via the Telegram Bot API's sendMessage
+// method.
+type TelegramNotifier struct {
+	BotToken string
+	ChatID   string
+}
+
+func (n *TelegramNotifier) Notify(ctx context.Context, msg Message) error {
+	endpoint := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", n.BotToken)
+
+	form := url.Values{}
+	form.Set("chat_id", n.ChatID)
+	form.Set("text", formatNotificationText(msg))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating Telegram request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending Telegram message: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Telegram API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
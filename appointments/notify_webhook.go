@@ -0,0 +1,50 @@
+package appointments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier POSTs the Message as JSON to a configured URL, signing
+// the body with HMAC-SHA256 the same way the backend signs its checksums.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling webhook payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", n.sign(body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(n.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	minPollInterval                  = time.Duration(180) * time.Second // Floor mandated by Berlin.de's IKT-ZMS team. Never violated.
+	maxPollInterval                  = 20 * time.Minute                 // Backed-off ceiling during historically dead hours.
+	backoffBase                      = 5 * time.Second
+	maxConsecutiveFailuresForBackoff = 10               // backoffBase*2^10 already exceeds maxPollInterval
+	hitRateJitter                    = 30 * time.Second // additive-only jitter so services launched back-to-back don't poll in lockstep
+)
+
+// scheduler decides how long to wait before the next poll of a single
+// service. It polls more aggressively during hours that have historically
+// had a high hit rate for new appointments, backs off up to maxPollInterval
+// during dead hours, and backs off further (with full jitter) on
+// consecutive upstream failures. It never returns an interval shorter than
+// minPollInterval.
+type scheduler struct {
+	serviceID        string
+	store            Store
+	consecutiveFails int
+}
+
+func newScheduler(serviceID string, store Store) *scheduler {
+	return &scheduler{serviceID: serviceID, store: store}
+}
+
+// next computes the interval to sleep before the next poll, given the
+// status of the poll that just completed, and logs the decision so the
+// 180s floor can be audited.
+func (s *scheduler) next(ctx context.Context, lastStatus int) time.Duration {
+	var interval time.Duration
+	var reason string
+
+	if lastStatus != 200 {
+		s.consecutiveFails++
+		interval, reason = s.backoffInterval()
+	} else {
+		s.consecutiveFails = 0
+		interval, reason = s.hitRateInterval(ctx)
+	}
+
+	if interval < minPollInterval {
+		interval = minPollInterval
+	}
+
+	fmt.Printf("[%s] next poll in %s (%s, floor=%s)\n", s.serviceID, interval.Round(time.Second), reason, minPollInterval)
+	return interval
+}
+
+// backoffInterval applies exponential backoff with full jitter on
+// consecutive non-200 responses, capped at maxPollInterval.
+func (s *scheduler) backoffInterval() (time.Duration, string) {
+	exponent := s.consecutiveFails
+	if exponent > maxConsecutiveFailuresForBackoff {
+		exponent = maxConsecutiveFailuresForBackoff
+	}
+	ceiling := backoffBase * time.Duration(int64(1)<<uint(exponent))
+	if ceiling > maxPollInterval {
+		ceiling = maxPollInterval
+	}
+
+	jittered := time.Duration(rand.Int63n(int64(ceiling)))
+	return jittered, fmt.Sprintf("backing off after %d consecutive failure(s)", s.consecutiveFails)
+}
+
+// hitRateInterval scales the interval between minPollInterval and
+// maxPollInterval inversely with the historical hit rate for the current
+// hour-of-week: a high hit rate polls near the floor, a dead hour polls
+// near the ceiling. A small additive jitter is layered on top so that
+// services started back-to-back (and new services with no history yet)
+// don't all land on Berlin.de on the same second.
+func (s *scheduler) hitRateInterval(ctx context.Context) (time.Duration, string) {
+	jitter := time.Duration(rand.Int63n(int64(hitRateJitter)))
+
+	hitRate, sampleSize := s.currentHourOfWeekHitRate(ctx)
+	if sampleSize == 0 {
+		return minPollInterval + jitter, "no history yet for this hour of the week"
+	}
+
+	span := maxPollInterval - minPollInterval
+	interval := minPollInterval + time.Duration(float64(span)*(1-hitRate)) + jitter
+	return interval, fmt.Sprintf("hit rate %.0f%% over %d sample(s) for this hour of the week", hitRate*100, sampleSize)
+}
+
+func hourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+func (s *scheduler) currentHourOfWeekHitRate(ctx context.Context) (hitRate float64, sampleSize int) {
+	observations, err := s.store.History(ctx, s.serviceID, time.Time{})
+	if err != nil {
+		fmt.Printf("Error reading history for scheduling: %v\n", err)
+		return 0, 0
+	}
+
+	target := hourOfWeek(time.Now().In(timezone))
+
+	var hits int
+	for _, obs := range observations {
+		if hourOfWeek(obs.Time.In(timezone)) != target {
+			continue
+		}
+		sampleSize++
+		if len(obs.SlotTimestamps) > 0 {
+			hits++
+		}
+	}
+
+	if sampleSize == 0 {
+		return 0, 0
+	}
+	return float64(hits) / float64(sampleSize), sampleSize
+}
@@ -0,0 +1,94 @@
+package appointments
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeHistoryStore is a minimal Store stub that only needs to answer
+// History for scheduler tests; the other methods are never exercised.
+type fakeHistoryStore struct {
+	observations []Observation
+}
+
+func (s *fakeHistoryStore) RecordObservation(ctx context.Context, obs Observation) error {
+	return nil
+}
+
+func (s *fakeHistoryStore) History(ctx context.Context, serviceID string, since time.Time) ([]Observation, error) {
+	return s.observations, nil
+}
+
+func (s *fakeHistoryStore) Services(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (s *fakeHistoryStore) Stats(ctx context.Context, serviceID string) (Stats, error) {
+	return Stats{}, nil
+}
+
+func (s *fakeHistoryStore) Close() error { return nil }
+
+func TestSchedulerNextNeverBelowFloor(t *testing.T) {
+	sched := newScheduler("svc", &fakeHistoryStore{})
+	for i := 0; i < 20; i++ {
+		if got := sched.next(context.Background(), 200); got < minPollInterval {
+			t.Fatalf("next() = %s, want >= minPollInterval (%s)", got, minPollInterval)
+		}
+	}
+}
+
+func TestHitRateIntervalNoHistoryStaysNearFloor(t *testing.T) {
+	sched := newScheduler("svc", &fakeHistoryStore{})
+	for i := 0; i < 20; i++ {
+		interval, _ := sched.hitRateInterval(context.Background())
+		if interval < minPollInterval || interval >= minPollInterval+hitRateJitter {
+			t.Fatalf("hitRateInterval() = %s, want in [%s, %s)", interval, minPollInterval, minPollInterval+hitRateJitter)
+		}
+	}
+}
+
+func TestHitRateIntervalHighHitRatePollsNearFloor(t *testing.T) {
+	now := time.Now().In(timezone)
+	var observations []Observation
+	for i := 0; i < 10; i++ {
+		observations = append(observations, Observation{
+			Time:           now.Add(-time.Duration(i) * 7 * 24 * time.Hour),
+			SlotTimestamps: []string{"2026-01-01T10:00:00Z"},
+		})
+	}
+	sched := newScheduler("svc", &fakeHistoryStore{observations: observations})
+
+	interval, _ := sched.hitRateInterval(context.Background())
+	if interval >= minPollInterval+hitRateJitter+time.Second {
+		t.Fatalf("hitRateInterval() = %s, want close to the floor for a 100%% hit rate", interval)
+	}
+}
+
+func TestHitRateIntervalDeadHourPollsNearCeiling(t *testing.T) {
+	now := time.Now().In(timezone)
+	var observations []Observation
+	for i := 0; i < 10; i++ {
+		observations = append(observations, Observation{
+			Time:           now.Add(-time.Duration(i) * 7 * 24 * time.Hour),
+			SlotTimestamps: nil,
+		})
+	}
+	sched := newScheduler("svc", &fakeHistoryStore{observations: observations})
+
+	interval, _ := sched.hitRateInterval(context.Background())
+	if interval < maxPollInterval-time.Second {
+		t.Fatalf("hitRateInterval() = %s, want close to the ceiling for a 0%% hit rate", interval)
+	}
+}
+
+func TestBackoffIntervalCapsAtCeiling(t *testing.T) {
+	sched := newScheduler("svc", &fakeHistoryStore{})
+	sched.consecutiveFails = maxConsecutiveFailuresForBackoff + 5
+
+	for i := 0; i < 20; i++ {
+		interval, _ := sched.backoffInterval()
+		if interval < 0 || interval > maxPollInterval {
+			t.Fatalf("backoffInterval() = %s, want in [0, %s]", interval, maxPollInterval)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package appointments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is a Store backed by a SQLite database file, so history
+// survives restarts.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the SQLite database at path
+// and ensures its schema exists.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite store: %v", err)
+	}
+
+	const schema = `
+		CREATE TABLE IF NOT EXISTS observations (
+			id              INTEGER PRIMARY KEY AUTOINCREMENT,
+			time            TEXT NOT NULL,
+			service_id      TEXT NOT NULL,
+			http_status     INTEGER NOT NULL,
+			slot_timestamps TEXT NOT NULL,
+			latency_ms      INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_observations_service_time ON observations (service_id, time);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating sqlite schema: %v", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) RecordObservation(ctx context.Context, obs Observation) error {
+	slots, err := json.Marshal(obs.SlotTimestamps)
+	if err != nil {
+		return fmt.Errorf("error marshalling slot timestamps: %v", err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO observations (time, service_id, http_status, slot_timestamps, latency_ms) VALUES (?, ?, ?, ?, ?)`,
+		obs.Time.Format(time.RFC3339), obs.ServiceID, obs.HTTPStatus, string(slots), obs.LatencyMs,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording observation: %v", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) History(ctx context.Context, serviceID string, since time.Time) ([]Observation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT time, service_id, http_status, slot_timestamps, latency_ms FROM observations WHERE service_id = ? AND time >= ? ORDER BY time ASC`,
+		serviceID, since.Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying history: %v", err)
+	}
+	defer rows.Close()
+
+	return scanObservations(rows)
+}
+
+func (s *SQLiteStore) Services(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT service_id FROM observations ORDER BY service_id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying services: %v", err)
+	}
+	defer rows.Close()
+
+	var services []string
+	for rows.Next() {
+		var serviceID string
+		if err := rows.Scan(&serviceID); err != nil {
+			return nil, fmt.Errorf("error scanning service id: %v", err)
+		}
+		services = append(services, serviceID)
+	}
+	return services, rows.Err()
+}
+
+func (s *SQLiteStore) Stats(ctx context.Context, serviceID string) (Stats, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT time, service_id, http_status, slot_timestamps, latency_ms FROM observations WHERE service_id = ? ORDER BY time ASC`,
+		serviceID,
+	)
+	if err != nil {
+		return Stats{}, fmt.Errorf("error querying stats: %v", err)
+	}
+	defer rows.Close()
+
+	observations, err := scanObservations(rows)
+	if err != nil {
+		return Stats{}, err
+	}
+	return statsFromObservations(serviceID, observations), nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func scanObservations(rows *sql.Rows) ([]Observation, error) {
+	var observations []Observation
+	for rows.Next() {
+		var (
+			timeStr   string
+			serviceID string
+			status    int
+			slotsJSON string
+			latency   int64
+		)
+		if err := rows.Scan(&timeStr, &serviceID, &status, &slotsJSON, &latency); err != nil {
+			return nil, fmt.Errorf("error scanning observation: %v", err)
+		}
+
+		obsTime, err := time.Parse(time.RFC3339, timeStr)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing observation time: %v", err)
+		}
+
+		var slots []string
+		if err := json.Unmarshal([]byte(slotsJSON), &slots); err != nil {
+			return nil, fmt.Errorf("error unmarshalling slot timestamps: %v", err)
+		}
+
+		observations = append(observations, Observation{
+			Time:           obsTime,
+			ServiceID:      serviceID,
+			HTTPStatus:     status,
+			SlotTimestamps: slots,
+			LatencyMs:      latency,
+		})
+	}
+	return observations, rows.Err()
+}
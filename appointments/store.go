@@ -0,0 +1,120 @@
+package appointments
+
+import (
+	"context"
+	"time"
+)
+
+// Observation is a single poll result recorded for a service.
+type Observation struct {
+	Time           time.Time
+	ServiceID      string
+	HTTPStatus     int
+	SlotTimestamps []string
+	LatencyMs      int64
+}
+
+// HourlyHitRate is the fraction of polls in a given hour-of-day that
+// surfaced at least one new appointment.
+type HourlyHitRate struct {
+	Hour    int     `json:"hour"`
+	HitRate float64 `json:"hitRate"`
+}
+
+// Stats summarizes a service's observation history.
+type Stats struct {
+	ServiceID              string          `json:"serviceId"`
+	Observations           int             `json:"observations"`
+	MedianTimeToFirstSlotS *float64        `json:"medianTimeToFirstSlotSeconds"`
+	HitRateByHour          []HourlyHitRate `json:"hitRateByHour"`
+	UpstreamUptimeRatio    float64         `json:"upstreamUptimeRatio"`
+}
+
+// Store records poll observations and answers questions about a service's
+// history, such as when during the day slots tend to appear.
+type Store interface {
+	RecordObservation(ctx context.Context, obs Observation) error
+	History(ctx context.Context, serviceID string, since time.Time) ([]Observation, error)
+	Services(ctx context.Context) ([]string, error)
+	Stats(ctx context.Context, serviceID string) (Stats, error)
+	Close() error
+}
+
+// statsFromObservations computes Stats from a service's full observation
+// history. It's shared by every Store implementation so the math only
+// lives in one place.
+func statsFromObservations(serviceID string, observations []Observation) Stats {
+	stats := Stats{ServiceID: serviceID, Observations: len(observations)}
+	if len(observations) == 0 {
+		return stats
+	}
+
+	var successCount int
+	hourTotals := make(map[int]int)
+	hourHits := make(map[int]int)
+	var timeToFirstSlot []float64
+
+	var dayStart time.Time
+	var dayHasSlot bool
+	for _, obs := range observations {
+		if obs.HTTPStatus == 200 {
+			successCount++
+		}
+
+		localTime := obs.Time.In(timezone)
+		hour := localTime.Hour()
+		hourTotals[hour]++
+		if len(obs.SlotTimestamps) > 0 {
+			hourHits[hour]++
+		}
+
+		dayOfObs := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, timezone)
+		if dayStart.IsZero() || !dayOfObs.Equal(dayStart) {
+			dayStart = dayOfObs
+			dayHasSlot = false
+		}
+		if len(obs.SlotTimestamps) > 0 && !dayHasSlot {
+			dayHasSlot = true
+			timeToFirstSlot = append(timeToFirstSlot, obs.Time.Sub(dayStart).Seconds())
+		}
+	}
+
+	stats.UpstreamUptimeRatio = float64(successCount) / float64(len(observations))
+
+	for hour := 0; hour < 24; hour++ {
+		total := hourTotals[hour]
+		if total == 0 {
+			continue
+		}
+		stats.HitRateByHour = append(stats.HitRateByHour, HourlyHitRate{
+			Hour:    hour,
+			HitRate: float64(hourHits[hour]) / float64(total),
+		})
+	}
+
+	if median := medianOf(timeToFirstSlot); median != nil {
+		stats.MedianTimeToFirstSlotS = median
+	}
+
+	return stats
+}
+
+func medianOf(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1] > sorted[j]; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	mid := len(sorted) / 2
+	var median float64
+	if len(sorted)%2 == 0 {
+		median = (sorted[mid-1] + sorted[mid]) / 2
+	} else {
+		median = sorted[mid]
+	}
+	return &median
+}
@@ -0,0 +1,86 @@
+package appointments
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMedianOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   *float64
+	}{
+		{"empty", nil, nil},
+		{"odd", []float64{3, 1, 2}, ptr(2)},
+		{"even", []float64{1, 2, 3, 4}, ptr(2.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := medianOf(tt.values)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("medianOf(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+			if got != nil && *got != *tt.want {
+				t.Fatalf("medianOf(%v) = %v, want %v", tt.values, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
+
+func TestStatsFromObservationsEmpty(t *testing.T) {
+	stats := statsFromObservations("svc", nil)
+	if stats.Observations != 0 || stats.MedianTimeToFirstSlotS != nil || stats.HitRateByHour != nil {
+		t.Fatalf("statsFromObservations(nil) = %+v, want zero value", stats)
+	}
+}
+
+func TestStatsFromObservationsUptimeAndHitRate(t *testing.T) {
+	base := time.Date(2026, 1, 5, 9, 0, 0, 0, timezone) // a Monday, 09:00 Berlin
+	observations := []Observation{
+		{Time: base, HTTPStatus: 200, SlotTimestamps: []string{"slot"}},
+		{Time: base.Add(time.Hour), HTTPStatus: 200, SlotTimestamps: nil},
+		{Time: base.Add(2 * time.Hour), HTTPStatus: 502, SlotTimestamps: nil},
+	}
+
+	stats := statsFromObservations("svc", observations)
+
+	if got, want := stats.Observations, 3; got != want {
+		t.Fatalf("Observations = %d, want %d", got, want)
+	}
+	if got, want := stats.UpstreamUptimeRatio, 2.0/3.0; got != want {
+		t.Fatalf("UpstreamUptimeRatio = %v, want %v", got, want)
+	}
+
+	var hitAt9 *HourlyHitRate
+	for i := range stats.HitRateByHour {
+		if stats.HitRateByHour[i].Hour == 9 {
+			hitAt9 = &stats.HitRateByHour[i]
+		}
+	}
+	if hitAt9 == nil || hitAt9.HitRate != 1.0 {
+		t.Fatalf("HitRateByHour for hour 9 = %+v, want HitRate 1.0", hitAt9)
+	}
+}
+
+func TestStatsFromObservationsTimeToFirstSlotUsesBerlinMidnight(t *testing.T) {
+	// 00:30 Berlin time should count as the same local day as 23:30 the
+	// previous UTC day, not roll over at the UTC day boundary.
+	dayStart := time.Date(2026, 1, 5, 0, 30, 0, 0, timezone)
+	observations := []Observation{
+		{Time: dayStart, SlotTimestamps: nil},
+		{Time: dayStart.Add(90 * time.Minute), SlotTimestamps: []string{"slot"}},
+	}
+
+	stats := statsFromObservations("svc", observations)
+
+	if stats.MedianTimeToFirstSlotS == nil {
+		t.Fatalf("MedianTimeToFirstSlotS = nil, want a value")
+	}
+	if got, want := *stats.MedianTimeToFirstSlotS, (2 * time.Hour).Seconds(); got != want {
+		t.Fatalf("MedianTimeToFirstSlotS = %v, want %v", got, want)
+	}
+}
@@ -2,13 +2,30 @@ package main
 
 import (
 	"burgeramt-appointment-finder/appointments"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
+// serviceURLList collects repeated -u flags into a slice.
+type serviceURLList []string
+
+func (l *serviceURLList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *serviceURLList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func askQuestion(question string, instructions string) string {
 	fmt.Printf("\033[1m%s\033[0m\n", question)
 	if instructions != "" {
@@ -20,14 +37,35 @@ func askQuestion(question string, instructions string) string {
 	return input
 }
 
+// loadServiceURLsFromConfig reads a JSON file containing an array of
+// service page URLs, e.g. ["https://service.berlin.de/dienstleistung/120686/"].
+func loadServiceURLsFromConfig(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var urls []string
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %v", err)
+	}
+	return urls, nil
+}
+
 func main() {
+	var urls serviceURLList
+
 	var (
-		id    = flag.String("i", "", "A unique ID for your script. Used by the Berlin.de team to identify requests from you.")
-		email = flag.String("e", "", "Your email address. Required by the Berlin.de team.")
-		url   = flag.String("u", "", "URL to the service page on Berlin.de. For example, \"https://service.berlin.de/dienstleistung/120686/\"")
-		quiet = flag.Bool("q", false, "Limit output to essential logging.")
-		port  = flag.Int("p", 80, "Port to use.")
+		id               = flag.String("i", "", "A unique ID for your script. Used by the Berlin.de team to identify requests from you.")
+		email            = flag.String("e", "", "Your email address. Required by the Berlin.de team.")
+		configPath       = flag.String("c", "", "Path to a JSON config file containing a list of service page URLs to watch.")
+		notifyConfigPath = flag.String("notify-config", "", "Path to a JSON config file enabling webhook, Telegram, ntfy, and/or email notifiers.")
+		quiet            = flag.Bool("q", false, "Limit output to essential logging.")
+		port             = flag.Int("p", 80, "Port to use.")
+		newOnly          = flag.Bool("new-only", false, "Default clients to only being pushed messages with appointments not seen in a previous poll.")
+		dbPath           = flag.String("db", "", "Path to a SQLite file to persist poll history to. Defaults to an in-memory store that is lost on restart.")
 	)
+	flag.Var(&urls, "u", "URL to a service page on Berlin.de. Repeatable. For example, \"https://service.berlin.de/dienstleistung/120686/\"")
 
 	flag.Parse()
 
@@ -37,12 +75,21 @@ func main() {
 		log.SetOutput(ioutil.Discard)
 	}
 
-	servicePageURL := *url
-	if servicePageURL == "" {
-		servicePageURL = askQuestion(
+	servicePageURLs := []string(urls)
+
+	if *configPath != "" {
+		configURLs, err := loadServiceURLsFromConfig(*configPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		servicePageURLs = append(servicePageURLs, configURLs...)
+	}
+
+	if len(servicePageURLs) == 0 {
+		servicePageURLs = append(servicePageURLs, askQuestion(
 			"What is the URL of the service you want to watch?",
 			"This is the service.berlin.de page for the service you want an appointment for. For example, \"https://service.berlin.de/dienstleistung/120686/\"",
-		)
+		))
 	}
 
 	userEmail := *email
@@ -53,5 +100,30 @@ func main() {
 		)
 	}
 
-	appointments.WatchForAppointments(servicePageURL, userEmail, *id, *port, *quiet)
+	var notifyConfig appointments.NotifierConfig
+	if *notifyConfigPath != "" {
+		var err error
+		notifyConfig, err = appointments.LoadNotifierConfig(*notifyConfigPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
+	notifiers := appointments.BuildNotifiers(notifyConfig)
+
+	var store appointments.Store
+	if *dbPath != "" {
+		sqliteStore, err := appointments.NewSQLiteStore(*dbPath)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		defer sqliteStore.Close()
+		store = sqliteStore
+	} else {
+		store = appointments.NewMemoryStore()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	appointments.WatchForAppointments(ctx, servicePageURLs, userEmail, *id, *port, *quiet, *newOnly, notifiers, store)
 }